@@ -0,0 +1,161 @@
+package morningpost
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Item represents a single news story in a form that is common to all news
+// sources.
+type Item struct {
+	Title string
+	URL   string
+	Score int
+}
+
+// StructuredSummarizer is the interface that wraps the Source and Items
+// methods.
+//
+// Source returns a human-readable name for the news source, used to attribute
+// its items. Items returns the news source's current stories. An error is
+// returned for any problems retrieving the stories (e.g. problems
+// communicating with an API, problems parsing API responses, etc.)
+type StructuredSummarizer interface {
+	Source() string
+	Items() ([]Item, error)
+}
+
+// digestEntry is an Item together with the names of every source that
+// reported it.
+type digestEntry struct {
+	Item
+	sources []string
+}
+
+/*
+BuildMarkdownDigest accepts a variable number of StructuredSummarizers
+representing news sources, retrieves their items concurrently and returns a
+single Markdown document like:
+
+	# Morning Post Digest
+
+	1. [Story Title 1](<https://story-title-1.com>) (score 42) — via HackerNews
+	2. [Story Title 2](<https://story-title2.com>) (score 7) — via HackerNews, Lobsters
+
+Items that share a canonical URL (see CanonicalURL) are merged into a single
+entry which takes its title and URL from the highest-scoring duplicate and is
+attributed to every source that reported it. Entries are ordered by descending
+score. An error is returned for any call to a StructuredSummarizer's Items()
+method that returns an error. If a call returns an error, the items from the
+other sources are still included in the document.
+*/
+func BuildMarkdownDigest(sources ...StructuredSummarizer) (string, error) {
+	results := make([][]Item, len(sources))
+	errs := make([]error, len(sources))
+	var wg sync.WaitGroup
+	for i, src := range sources {
+		wg.Add(1)
+		go func(i int, src StructuredSummarizer) {
+			defer wg.Done()
+			items, err := src.Items()
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", src.Source(), err)
+				return
+			}
+			results[i] = items
+		}(i, src)
+	}
+	wg.Wait()
+	var entries []*digestEntry
+	byKey := map[string]*digestEntry{}
+	for i, items := range results {
+		name := sources[i].Source()
+		for _, item := range items {
+			key := digestKey(item)
+			e, ok := byKey[key]
+			if !ok {
+				e = &digestEntry{Item: item}
+				byKey[key] = e
+				entries = append(entries, e)
+			}
+			if item.Score > e.Score {
+				e.Item = item
+			}
+			if !slices.Contains(e.sources, name) {
+				e.sources = append(e.sources, name)
+			}
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b *digestEntry) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	var b strings.Builder
+	b.WriteString("# Morning Post Digest\n\n")
+	for i, e := range entries {
+		fmt.Fprintf(&b, "%d. %s (score %d) — via %s\n",
+			i+1, markdownLink(e.Title, e.URL), e.Score, strings.Join(e.sources, ", "))
+	}
+	return b.String(), errors.Join(errs...)
+}
+
+// CanonicalURL returns a normalized form of rawURL suitable for detecting
+// duplicate stories. The scheme and host are lower-cased, a leading "www." is
+// removed from the host, "http" is treated the same as "https", and any
+// fragment, trailing slash and "utm_" tracking query parameters are dropped.
+// All other query parameters are kept exactly as they appear in rawURL. An
+// error is returned if rawURL cannot be parsed.
+func CanonicalURL(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme == "http" {
+		u.Scheme = "https"
+	}
+	u.Host = strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = strings.TrimSuffix(u.RawPath, "/")
+	var params []string
+	for _, p := range strings.Split(u.RawQuery, "&") {
+		if p != "" && !strings.HasPrefix(p, "utm_") {
+			params = append(params, p)
+		}
+	}
+	u.RawQuery = strings.Join(params, "&")
+	u.ForceQuery = false
+	return u.String(), nil
+}
+
+// digestKey returns the key used to dedupe item, which is its canonical URL.
+// Items without a parseable URL (e.g. HackerNews "Ask HN" posts) are keyed by
+// their title instead.
+func digestKey(item Item) string {
+	if item.URL != "" {
+		if canon, err := CanonicalURL(item.URL); err == nil {
+			return "url:" + canon
+		}
+	}
+	return "title:" + item.Title
+}
+
+// markdownLink renders title as a Markdown link to link, or as plain text if
+// link is empty. Line breaks in title are replaced with spaces so that the
+// link stays on one line, and the link destination is wrapped in angle
+// brackets so that spaces and unbalanced parentheses in link don't end it
+// early.
+func markdownLink(title, link string) string {
+	title = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`, "\r", " ", "\n", " ").Replace(title)
+	if link == "" {
+		return title
+	}
+	link = strings.NewReplacer(`\`, `\\`, `<`, `\<`, `>`, `\>`, "\r", "%0D", "\n", "%0A").Replace(link)
+	return "[" + title + "](<" + link + ">)"
+}
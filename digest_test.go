@@ -0,0 +1,119 @@
+package morningpost_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aculclasure/morningpost"
+	"github.com/google/go-cmp/cmp"
+)
+
+type mockStructuredSummarizer struct {
+	source string
+	items  []morningpost.Item
+	err    error
+}
+
+func (m *mockStructuredSummarizer) Source() string {
+	return m.source
+}
+
+func (m *mockStructuredSummarizer) Items() ([]morningpost.Item, error) {
+	return m.items, m.err
+}
+
+func TestBuildMarkdownDigest_DedupesSortsAndAttributesItemsFromAllSources(t *testing.T) {
+	t.Parallel()
+	s1 := &mockStructuredSummarizer{
+		source: "Source One",
+		items: []morningpost.Item{
+			{Title: "Shared Story", URL: "http://www.example.com/shared/?utm_source=feed#c", Score: 10},
+			{Title: "Low Story", URL: "https://low.example.com", Score: 1},
+			{Title: "HTTP Only Story", URL: "http://www.neverssl.com/x?b=2&a=1", Score: 5},
+		},
+	}
+	s2 := &mockStructuredSummarizer{
+		source: "Source Two",
+		items: []morningpost.Item{
+			{Title: "Top Story", URL: "https://top.example.com", Score: 50},
+			{Title: "Shared Story", URL: "https://example.com/shared", Score: 20},
+		},
+	}
+	want := "# Morning Post Digest\n\n" +
+		"1. [Top Story](<https://top.example.com>) (score 50) — via Source Two\n" +
+		"2. [Shared Story](<https://example.com/shared>) (score 20) — via Source One, Source Two\n" +
+		"3. [HTTP Only Story](<http://www.neverssl.com/x?b=2&a=1>) (score 5) — via Source One\n" +
+		"4. [Low Story](<https://low.example.com>) (score 1) — via Source One\n"
+	got, err := morningpost.BuildMarkdownDigest(s1, s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestBuildMarkdownDigest_IncludesValidSourcesAndReturnsErrorForInvalidSources(t *testing.T) {
+	t.Parallel()
+	s1 := &mockStructuredSummarizer{source: "Broken", err: errors.New("oh no!")}
+	s2 := &mockStructuredSummarizer{
+		source: "Working",
+		items:  []morningpost.Item{{Title: "Story", URL: "https://example.com", Score: 3}},
+	}
+	got, err := morningpost.BuildMarkdownDigest(s1, s2)
+	if err == nil {
+		t.Fatal("expected an error for invalid source but got nil")
+	}
+	want := "# Morning Post Digest\n\n" +
+		"1. [Story](<https://example.com>) (score 3) — via Working\n"
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestBuildMarkdownDigest_EscapesTitlesAndLinks(t *testing.T) {
+	t.Parallel()
+	s := &mockStructuredSummarizer{
+		source: "Source",
+		items: []morningpost.Item{
+			{Title: `Paths like C:\`, URL: "https://example.com/wiki/Go_(language", Score: 2},
+			{Title: "Ask: [why] not?", Score: 1},
+			{Title: "line1\nline2", URL: "https://example.com/lines", Score: 0},
+		},
+	}
+	want := "# Morning Post Digest\n\n" +
+		"1. [Paths like C:\\\\](<https://example.com/wiki/Go_(language>) (score 2) — via Source\n" +
+		"2. Ask: \\[why\\] not? (score 1) — via Source\n" +
+		"3. [line1 line2](<https://example.com/lines>) (score 0) — via Source\n"
+	got, err := morningpost.BuildMarkdownDigest(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestCanonicalURL_NormalizesEquivalentURLs(t *testing.T) {
+	t.Parallel()
+	tcs := []struct {
+		in, want string
+	}{
+		{in: "https://example.com/a?id=1", want: "https://example.com/a?id=1"},
+		{in: "HTTP://WWW.Example.com/a/?id=1", want: "https://example.com/a?id=1"},
+		{in: "https://example.com/a?id=1&utm_medium=rss#top", want: "https://example.com/a?id=1"},
+		{in: "http://www.example.com/a?x=1;y=2", want: "https://example.com/a?x=1;y=2"},
+		{in: "https://example.com/a?b=2&utm_source=feed&a=1", want: "https://example.com/a?b=2&a=1"},
+		{in: "https://example.com/?", want: "https://example.com"},
+		{in: "https://example.com/?utm_source=feed", want: "https://example.com"},
+	}
+	for _, tc := range tcs {
+		got, err := morningpost.CanonicalURL(tc.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.want != got {
+			t.Errorf("CanonicalURL(%q): want %q, got %q", tc.in, tc.want, got)
+		}
+	}
+}
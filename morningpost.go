@@ -4,6 +4,8 @@
 package morningpost
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,26 +15,35 @@ import (
 	"time"
 )
 
+// defaultNumStories is the number of stories included by Summary and Items
+// for a client returned by NewHNClient.
+const defaultNumStories = 10
+
 // HNClient provides a client for interacting with the HackerNews API. For details
 // about the HackerNews API, please see https://github.com/HackerNews/API.
 type HNClient struct {
 	BaseURL    string
 	HttpClient *http.Client
+	// NumStories is the maximum number of stories included by Summary and
+	// Items. If it is less than or equal to zero, defaultNumStories is used.
+	NumStories int
 }
 
 // NewHNClient returns a client that is ready to interact with the HackerNews
-// API at https://hacker-news.firebaseio.com.
+// API at https://hacker-news.firebaseio.com and that summarizes the
+// defaultNumStories newest stories.
 func NewHNClient() *HNClient {
 	return &HNClient{
 		BaseURL: "https://hacker-news.firebaseio.com",
 		HttpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		NumStories: defaultNumStories,
 	}
 }
 
 /*
-Summary returns the 10 newest HackerNews story items as a string of
+Summary returns the NumStories newest HackerNews story items as a string of
 line-separated story titles and URLs like:
 
 	Story Title 1
@@ -45,48 +56,155 @@ An error is returned if the client has a problem generating the list of newest
 story IDs or generating the details for a particular story.
 */
 func (h *HNClient) Summary() (string, error) {
-	storyIDs, err := h.NewestStories()
+	return h.SummaryContext(context.Background())
+}
+
+// SummaryContext is like Summary but uses ctx for every request it makes to
+// the HackerNews API, so the caller can control cancellation and timeouts. An
+// error is returned if ctx is cancelled before the summary is complete.
+func (h *HNClient) SummaryContext(ctx context.Context) (string, error) {
+	stories, err := h.newestStoryDetails(ctx, h.numStories())
 	if err != nil {
 		return "", err
 	}
-	maxNumStories := 10
-	if len(storyIDs) < maxNumStories {
-		maxNumStories = len(storyIDs)
-	}
 	summary := "Latest HackerNews Stories\n=========================\n\n"
-	for i := 0; i < maxNumStories; i++ {
-		story, err := h.Story(storyIDs[i])
-		if err != nil {
-			return "", err
-		}
+	for _, story := range stories {
 		summary += story.Title + "\n" + story.Url + "\n\n"
 	}
 	return summary, nil
 }
 
-// NewestStories queries the HackerNews API for the newest story items and
-// returns a slice of ints representing the item IDs of these stories. An error
-// is returned if there is a problem communicating with the API, if an invalid
-// HTTP response code is received, or if the response cannot be parsed into an
-// int slice.
-func (h *HNClient) NewestStories() ([]int, error) {
-	resp, err := h.HttpClient.Get(h.BaseURL + "/v0/newstories.json")
+// Source returns the name of the HackerNews news source. It is used to
+// attribute items in a digest built by BuildMarkdownDigest.
+func (h *HNClient) Source() string {
+	return "HackerNews"
+}
+
+// Items returns the NumStories newest HackerNews story items as a slice of
+// Items. An error is returned if the client has a problem generating the list
+// of newest story IDs or generating the details for a particular story.
+func (h *HNClient) Items() ([]Item, error) {
+	return h.ItemsContext(context.Background())
+}
+
+// ItemsContext is like Items but uses ctx for every request it makes to the
+// HackerNews API. An error is returned if ctx is cancelled before the items
+// are complete.
+func (h *HNClient) ItemsContext(ctx context.Context) ([]Item, error) {
+	stories, err := h.newestStoryDetails(ctx, h.numStories())
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("got unexpected response code %d", resp.StatusCode)
+	items := make([]Item, 0, len(stories))
+	for _, story := range stories {
+		items = append(items, Item{
+			Title: story.Title,
+			URL:   story.Url,
+			Score: story.Score,
+		})
 	}
-	data, err := io.ReadAll(resp.Body)
+	return items, nil
+}
+
+// numStories returns the number of stories to include in Summary and Items.
+func (h *HNClient) numStories() int {
+	if h.NumStories <= 0 {
+		return defaultNumStories
+	}
+	return h.NumStories
+}
+
+// newestStoryDetails returns the details for up to n of the newest HackerNews
+// stories, in the order returned by the newest stories endpoint. Deleted and
+// dead items, and items without a title, are skipped. Story IDs are read from
+// the newest stories endpoint in batches of 2*n, and each response is closed
+// before the stories in its batch are fetched, so that the client's timeout
+// never has to cover both reading a large response and fetching many stories.
+// Only as much of the response as is needed is decoded, so the rest of a large
+// response is never loaded into memory.
+func (h *HNClient) newestStoryDetails(ctx context.Context, n int) ([]HNStory, error) {
+	batchSize := 2 * n
+	seen := map[int]bool{}
+	stories := make([]HNStory, 0, n)
+	for offset := 0; len(stories) < n; offset += batchSize {
+		ids, err := h.newestStoryIDs(ctx, offset+batchSize)
+		if err != nil {
+			return nil, err
+		}
+		if offset >= len(ids) {
+			break
+		}
+		for _, id := range ids[offset:] {
+			if len(stories) == n {
+				break
+			}
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			story, err := h.StoryContext(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if story.Deleted || story.Dead || story.Title == "" {
+				continue
+			}
+			stories = append(stories, story)
+		}
+		if len(ids) < offset+batchSize {
+			break
+		}
+	}
+	return stories, nil
+}
+
+// newestStoryIDs queries the HackerNews API for the newest story items and
+// returns up to limit of their item IDs. The response is closed as soon as
+// limit IDs have been decoded.
+func (h *HNClient) newestStoryIDs(ctx context.Context, limit int) ([]int, error) {
+	body, err := h.openNewestStories(ctx)
 	if err != nil {
 		return nil, err
 	}
-	newest, err := ParseHNNewestStoriesResponse(data)
+	defer body.Close()
+	return DecodeHNNewestStories(body, limit)
+}
+
+// openNewestStories queries the HackerNews API's newest stories endpoint and
+// returns the response body. The caller must close the returned body. An
+// error is returned if there is a problem communicating with the API or if an
+// invalid HTTP response code is received.
+func (h *HNClient) openNewestStories(ctx context.Context) (io.ReadCloser, error) {
+	resp, err := h.get(ctx, h.BaseURL+"/v0/newstories.json")
 	if err != nil {
 		return nil, err
 	}
-	return newest, nil
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("got unexpected response code %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// NewestStories queries the HackerNews API for the newest story items and
+// returns a slice of ints representing the item IDs of these stories. An error
+// is returned if there is a problem communicating with the API, if an invalid
+// HTTP response code is received, or if the response cannot be parsed into an
+// int slice.
+func (h *HNClient) NewestStories() ([]int, error) {
+	return h.NewestStoriesContext(context.Background())
+}
+
+// NewestStoriesContext is like NewestStories but uses ctx for the request to
+// the HackerNews API. An error is returned if ctx is cancelled before the
+// request completes.
+func (h *HNClient) NewestStoriesContext(ctx context.Context) ([]int, error) {
+	body, err := h.openNewestStories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return DecodeHNNewestStories(body, 0)
 }
 
 // Story queries the HackerNews API for the item with the given id and returns
@@ -94,7 +212,13 @@ func (h *HNClient) NewestStories() ([]int, error) {
 // problem communicating with the API, if an invalid HTTP reponse code is
 // received, or if the response cannot be parsed into a HNStory struct.
 func (h *HNClient) Story(id int) (HNStory, error) {
-	resp, err := h.HttpClient.Get(fmt.Sprintf("%s/v0/item/%d.json", h.BaseURL, id))
+	return h.StoryContext(context.Background(), id)
+}
+
+// StoryContext is like Story but uses ctx for the request to the HackerNews
+// API. An error is returned if ctx is cancelled before the request completes.
+func (h *HNClient) StoryContext(ctx context.Context, id int) (HNStory, error) {
+	resp, err := h.get(ctx, fmt.Sprintf("%s/v0/item/%d.json", h.BaseURL, id))
 	if err != nil {
 		return HNStory{}, err
 	}
@@ -113,23 +237,104 @@ func (h *HNClient) Story(id int) (HNStory, error) {
 	return story, nil
 }
 
+// get issues a GET request for url using the client's HttpClient, bound to
+// ctx.
+func (h *HNClient) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return h.HttpClient.Do(req)
+}
+
 // ParseHNNewestStoriesResponse accepts a slice of bytes representing a response
 // to a query of the HackerNews API's newest stories endpoint and returns a
 // slice of ints containing the item IDs of the newest stories. An error is
 // returned if there is a problem parsing the response data into a slice of ints.
 func ParseHNNewestStoriesResponse(data []byte) ([]int, error) {
-	var hnResp []int
-	err := json.Unmarshal(data, &hnResp)
+	return DecodeHNNewestStories(bytes.NewReader(data), 0)
+}
+
+// DecodeHNNewestStories reads a response to a query of the HackerNews API's
+// newest stories endpoint from r and returns a slice of ints containing up to
+// limit of the item IDs of the newest stories. The IDs are decoded one at a
+// time and decoding stops as soon as limit IDs have been read, without
+// consuming the remainder of r. If limit is less than or equal to zero, every
+// ID is returned. An error is returned if there is a problem parsing the data
+// read from r, or if the array is followed by anything other than whitespace.
+func DecodeHNNewestStories(r io.Reader, limit int) ([]int, error) {
+	dec := newStoryIDDecoder(r)
+	var ids []int
+	for limit <= 0 || len(ids) < limit {
+		id, ok, err := dec.Next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// storyIDDecoder decodes the item IDs in a response to a query of the
+// HackerNews API's newest stories endpoint one at a time.
+type storyIDDecoder struct {
+	dec     *json.Decoder
+	started bool
+	done    bool
+}
+
+func newStoryIDDecoder(r io.Reader) *storyIDDecoder {
+	return &storyIDDecoder{dec: json.NewDecoder(r)}
+}
+
+// Next returns the next item ID and true, or false once the end of the JSON
+// array has been reached. An error is returned if the data read so far is not
+// a valid JSON array of ints, or if anything other than whitespace follows the
+// end of the array.
+func (d *storyIDDecoder) Next() (int, bool, error) {
+	if d.done {
+		return 0, false, nil
+	}
+	if !d.started {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid API response: %w", err)
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return 0, false, fmt.Errorf("invalid API response: want JSON array, got %v", tok)
+		}
+		d.started = true
+	}
+	if !d.dec.More() {
+		_, err := d.dec.Token()
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid API response: %w", err)
+		}
+		_, err = d.dec.Token()
+		if err != io.EOF {
+			return 0, false, errors.New("invalid API response: unexpected data after JSON array")
+		}
+		d.done = true
+		return 0, false, nil
+	}
+	var id int
+	err := d.dec.Decode(&id)
 	if err != nil {
-		return nil, fmt.Errorf("invalid API response %s: %w", data, err)
+		return 0, false, fmt.Errorf("invalid API response: %w", err)
 	}
-	return hnResp, nil
+	return id, true, nil
 }
 
 // HNStory represents a HackerNews API story item.
 type HNStory struct {
-	Title string
-	Url   string
+	Title   string
+	Url     string
+	Score   int
+	Deleted bool
+	Dead    bool
 }
 
 // ParseHNStoryResponse accepts a slice of bytes representing a response to a
@@ -2,11 +2,15 @@ package morningpost_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/aculclasure/morningpost"
@@ -55,6 +59,7 @@ func TestStory_ReturnsExpectedHNStory(t *testing.T) {
 	want := morningpost.HNStory{
 		Title: "Computer-Based System Safety Essential Reading List",
 		Url:   "http://safeautonomy.blogspot.com/p/safe-autonomy.html",
+		Score: 1,
 	}
 	got, err := c.Story(wantStoryID)
 	if err != nil {
@@ -65,6 +70,118 @@ func TestStory_ReturnsExpectedHNStory(t *testing.T) {
 	}
 }
 
+func newCancelledContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+func newUnreachableTestClient(t *testing.T) *morningpost.HNClient {
+	t.Helper()
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s with cancelled context", r.RequestURI)
+	}))
+	t.Cleanup(ts.Close)
+	c := morningpost.NewHNClient()
+	c.BaseURL = ts.URL
+	c.HttpClient = ts.Client()
+	return c
+}
+
+func TestNewestStoriesContext_ReturnsErrorGivenCancelledContext(t *testing.T) {
+	t.Parallel()
+	c := newUnreachableTestClient(t)
+	_, err := c.NewestStoriesContext(newCancelledContext())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want error %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestStoryContext_ReturnsErrorGivenCancelledContext(t *testing.T) {
+	t.Parallel()
+	c := newUnreachableTestClient(t)
+	_, err := c.StoryContext(newCancelledContext(), 38777401)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want error %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestSummaryContext_ReturnsErrorGivenCancelledContext(t *testing.T) {
+	t.Parallel()
+	c := newUnreachableTestClient(t)
+	_, err := c.SummaryContext(newCancelledContext())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want error %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestItemsContext_ReturnsErrorGivenCancelledContext(t *testing.T) {
+	t.Parallel()
+	c := newUnreachableTestClient(t)
+	_, err := c.ItemsContext(newCancelledContext())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want error %v, got %v", context.Canceled, err)
+	}
+}
+
+// cancelAfterNewestStories is an http.RoundTripper that reads the whole
+// newest stories response into memory and then calls cancel, so that the
+// context is cancelled after the story IDs have been received but before any
+// story item is requested.
+type cancelAfterNewestStories struct {
+	rt     http.RoundTripper
+	cancel context.CancelFunc
+}
+
+func (c *cancelAfterNewestStories) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := c.rt.RoundTrip(r)
+	if err != nil || r.URL.Path != "/v0/newstories.json" {
+		return resp, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	c.cancel()
+	return resp, nil
+}
+
+func TestSummaryAndItemsContext_UseContextForStoryRequests(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.RequestURI == "/v0/newstories.json" {
+			fmt.Fprint(w, "[38776446, 38776437]")
+			return
+		}
+		t.Errorf("unexpected request to %s after context was cancelled", r.RequestURI)
+	}))
+	defer ts.Close()
+	calls := map[string]func(*morningpost.HNClient, context.Context) error{
+		"SummaryContext": func(c *morningpost.HNClient, ctx context.Context) error {
+			_, err := c.SummaryContext(ctx)
+			return err
+		},
+		"ItemsContext": func(c *morningpost.HNClient, ctx context.Context) error {
+			_, err := c.ItemsContext(ctx)
+			return err
+		},
+	}
+	for name, call := range calls {
+		ctx, cancel := context.WithCancel(context.Background())
+		c := morningpost.NewHNClient()
+		c.BaseURL = ts.URL
+		c.HttpClient = &http.Client{
+			Transport: &cancelAfterNewestStories{rt: ts.Client().Transport, cancel: cancel},
+		}
+		err := call(c, ctx)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: want error %v, got %v", name, context.Canceled, err)
+		}
+	}
+}
+
 func TestParseHNNewestStoriesResponse_CorrectlyParsesJSONResponse(t *testing.T) {
 	t.Parallel()
 	data := []byte(`[38776446, 38776437]`)
@@ -87,6 +204,285 @@ func TestParseHNNewestStoriesResponse_ReturnsErrorGivenInvalidResponse(t *testin
 	}
 }
 
+// endlessIDStream is an io.Reader producing a JSON array of story IDs that
+// never ends. It records how many bytes have been read from it.
+type endlessIDStream struct {
+	next      int
+	pending   []byte
+	bytesRead int
+}
+
+func (e *endlessIDStream) Read(p []byte) (int, error) {
+	for len(e.pending) < len(p) {
+		if e.next == 0 {
+			e.pending = append(e.pending, '[')
+		} else {
+			e.pending = append(e.pending, ',')
+		}
+		e.next++
+		e.pending = strconv.AppendInt(e.pending, int64(e.next), 10)
+	}
+	n := copy(p, e.pending)
+	e.pending = e.pending[n:]
+	e.bytesRead += n
+	return n, nil
+}
+
+func TestDecodeHNNewestStories_StopsReadingOnceLimitIDsAreDecoded(t *testing.T) {
+	t.Parallel()
+	stream := &endlessIDStream{}
+	want := []int{1, 2, 3, 4, 5}
+	got, err := morningpost.DecodeHNNewestStories(stream, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if stream.bytesRead > 64*1024 {
+		t.Errorf("want early termination, but %d bytes were read", stream.bytesRead)
+	}
+}
+
+func TestDecodeHNNewestStories_ReturnsAllIDsGivenNoLimit(t *testing.T) {
+	t.Parallel()
+	data := new(bytes.Buffer)
+	data.WriteString("[")
+	var want []int
+	for i := 1; i <= 100000; i++ {
+		if i > 1 {
+			data.WriteString(",")
+		}
+		data.WriteString(strconv.Itoa(i))
+		want = append(want, i)
+	}
+	data.WriteString("]")
+	got, err := morningpost.DecodeHNNewestStories(data, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestDecodeHNNewestStories_ReturnsErrorGivenInvalidResponse(t *testing.T) {
+	t.Parallel()
+	for _, data := range []string{`{}`, `[1, "not-an-int"]`, `[1, 2`, `[1, 2]garbage`, `[1, 2] [3]`} {
+		_, err := morningpost.DecodeHNNewestStories(bytes.NewBufferString(data), 0)
+		if err == nil {
+			t.Errorf("want error decoding invalid response %s, got nil", data)
+		}
+	}
+}
+
+func TestItems_FetchesOnlyNumStoriesFromLargeNewestStoriesResponse(t *testing.T) {
+	t.Parallel()
+	stream := &endlessIDStream{}
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.RequestURI == "/v0/newstories.json" {
+			io.Copy(w, io.LimitReader(stream, 1<<30))
+			return
+		}
+		var id int
+		_, err := fmt.Sscanf(r.RequestURI, "/v0/item/%d.json", &id)
+		if err != nil {
+			t.Errorf("unexpected request URI %s", r.RequestURI)
+			return
+		}
+		fmt.Fprintf(w, `{"title": "Story %d", "url": "https://example.com/%d", "score": %d}`, id, id, id)
+	}))
+	defer ts.Close()
+	c := morningpost.NewHNClient()
+	c.BaseURL = ts.URL
+	c.HttpClient = ts.Client()
+	c.NumStories = 2
+	want := []morningpost.Item{
+		{Title: "Story 1", URL: "https://example.com/1", Score: 1},
+		{Title: "Story 2", URL: "https://example.com/2", Score: 2},
+	}
+	got, err := c.Items()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	// Closing the server waits for the newest stories handler to give up
+	// writing, which it does once the client stops reading the response.
+	ts.Close()
+	if stream.bytesRead > 32<<20 {
+		t.Errorf("want early termination, but server wrote %d bytes of newest stories", stream.bytesRead)
+	}
+}
+
+func TestItems_SkipsDeletedDeadAndNullItemsUntilNumStoriesAreGathered(t *testing.T) {
+	t.Parallel()
+	items := map[string]string{
+		"/v0/item/1.json": `null`,
+		"/v0/item/2.json": `{"id": 2, "deleted": true}`,
+		"/v0/item/3.json": `{"id": 3, "title": "Dead Story", "url": "https://example.com/3", "dead": true}`,
+		"/v0/item/4.json": `{"id": 4, "title": "Story 4", "url": "https://example.com/4", "score": 4}`,
+		"/v0/item/5.json": `{"id": 5, "title": "Story 5", "url": "https://example.com/5", "score": 5}`,
+	}
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.RequestURI == "/v0/newstories.json" {
+			fmt.Fprint(w, "[1, 2, 3, 4, 5, 6]")
+			return
+		}
+		item, ok := items[r.RequestURI]
+		if !ok {
+			t.Errorf("unexpected request URI %s", r.RequestURI)
+		}
+		fmt.Fprint(w, item)
+	}))
+	defer ts.Close()
+	c := morningpost.NewHNClient()
+	c.BaseURL = ts.URL
+	c.HttpClient = ts.Client()
+	c.NumStories = 2
+	want := []morningpost.Item{
+		{Title: "Story 4", URL: "https://example.com/4", Score: 4},
+		{Title: "Story 5", URL: "https://example.com/5", Score: 5},
+	}
+	got, err := c.Items()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestItems_ReturnsDefaultNumberOfStoriesGivenClientWithoutNumStories(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.RequestURI == "/v0/newstories.json" {
+			io.Copy(w, io.LimitReader(&endlessIDStream{}, 1<<20))
+			return
+		}
+		fmt.Fprint(w, `{"title": "Story", "url": "https://example.com"}`)
+	}))
+	defer ts.Close()
+	c := &morningpost.HNClient{
+		BaseURL:    ts.URL,
+		HttpClient: ts.Client(),
+	}
+	got, err := c.Items()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 10 {
+		t.Errorf("want 10 items, got %d", len(got))
+	}
+}
+
+func TestParseHNNewestStoriesResponse_ReturnsErrorGivenTrailingData(t *testing.T) {
+	t.Parallel()
+	_, err := morningpost.ParseHNNewestStoriesResponse([]byte(`[1, 2]garbage`))
+	if err == nil {
+		t.Fatal("want error parsing response with trailing data, got nil")
+	}
+}
+
+func TestDecodeHNNewestStories_AcceptsTrailingWhitespace(t *testing.T) {
+	t.Parallel()
+	want := []int{1, 2}
+	got, err := morningpost.DecodeHNNewestStories(bytes.NewBufferString("[1, 2]\n"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+// newestStoriesBodyTracker is an http.RoundTripper that counts requests for
+// the newest stories endpoint and reports an error for any item request made
+// while a newest stories response body is still open.
+type newestStoriesBodyTracker struct {
+	t        *testing.T
+	rt       http.RoundTripper
+	mu       sync.Mutex
+	open     int
+	requests int
+}
+
+func (n *newestStoriesBodyTracker) RoundTrip(r *http.Request) (*http.Response, error) {
+	n.mu.Lock()
+	if r.URL.Path == "/v0/newstories.json" {
+		n.requests++
+	} else if n.open > 0 {
+		n.t.Errorf("request to %s while newest stories response is still open", r.URL.Path)
+	}
+	n.mu.Unlock()
+	resp, err := n.rt.RoundTrip(r)
+	if err != nil || r.URL.Path != "/v0/newstories.json" {
+		return resp, err
+	}
+	n.mu.Lock()
+	n.open++
+	n.mu.Unlock()
+	resp.Body = &trackedBody{ReadCloser: resp.Body, onClose: func() {
+		n.mu.Lock()
+		n.open--
+		n.mu.Unlock()
+	}}
+	return resp, nil
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	once    sync.Once
+	onClose func()
+}
+
+func (b *trackedBody) Close() error {
+	b.once.Do(b.onClose)
+	return b.ReadCloser.Close()
+}
+
+func TestItems_ClosesNewestStoriesResponseBeforeFetchingEachBatchOfStories(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.RequestURI == "/v0/newstories.json" {
+			io.Copy(w, io.LimitReader(&endlessIDStream{}, 1<<20))
+			return
+		}
+		var id int
+		_, err := fmt.Sscanf(r.RequestURI, "/v0/item/%d.json", &id)
+		if err != nil {
+			t.Errorf("unexpected request URI %s", r.RequestURI)
+			return
+		}
+		if id <= 4 {
+			fmt.Fprint(w, "null")
+			return
+		}
+		fmt.Fprintf(w, `{"title": "Story %d", "url": "https://example.com/%d", "score": %d}`, id, id, id)
+	}))
+	defer ts.Close()
+	tracker := &newestStoriesBodyTracker{t: t, rt: ts.Client().Transport}
+	c := morningpost.NewHNClient()
+	c.BaseURL = ts.URL
+	c.HttpClient = &http.Client{Transport: tracker}
+	c.NumStories = 2
+	want := []morningpost.Item{
+		{Title: "Story 5", URL: "https://example.com/5", Score: 5},
+		{Title: "Story 6", URL: "https://example.com/6", Score: 6},
+	}
+	got, err := c.Items()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if tracker.requests != 2 {
+		t.Errorf("want 2 batches of newest stories requested, got %d", tracker.requests)
+	}
+}
+
 func TestParseHNStoryResponse_CorrectlyParsesJSONResponse(t *testing.T) {
 	t.Parallel()
 	data, err := os.ReadFile("testdata/hackernews_story_item_response.json")
@@ -96,6 +492,7 @@ func TestParseHNStoryResponse_CorrectlyParsesJSONResponse(t *testing.T) {
 	want := morningpost.HNStory{
 		Title: "Computer-Based System Safety Essential Reading List",
 		Url:   "http://safeautonomy.blogspot.com/p/safe-autonomy.html",
+		Score: 1,
 	}
 	got, err := morningpost.ParseHNStoryResponse(data)
 	if err != nil {
@@ -0,0 +1,178 @@
+package morningpost
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Clock is the interface that wraps the Now and NewTimer methods, allowing a
+// Scheduler to be driven by something other than the system clock.
+//
+// Now returns the current time. NewTimer starts a timer that sends the current
+// time on the returned channel once the duration d has elapsed, and returns a
+// function that stops the timer. The stop function reports whether it stopped
+// the timer before it fired, like time.Timer's Stop method.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) (<-chan time.Time, func() bool)
+}
+
+// systemClock is a Clock backed by the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	t := time.NewTimer(d)
+	return t.C, t.Stop
+}
+
+// ScheduleSpec is the interface that wraps the basic Next method.
+//
+// Next returns the first scheduled time strictly after t.
+type ScheduleSpec interface {
+	Next(t time.Time) time.Time
+}
+
+// intervalSpec schedules runs at a fixed interval.
+type intervalSpec struct {
+	interval time.Duration
+}
+
+func (s intervalSpec) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+// dailySpec schedules runs once a day at a fixed time of day, in the location
+// of the time passed to Next.
+type dailySpec struct {
+	hour, minute int
+}
+
+func (s dailySpec) Next(t time.Time) time.Time {
+	next := time.Date(t.Year(), t.Month(), t.Day(), s.hour, s.minute, 0, 0, t.Location())
+	if !next.After(t) {
+		next = time.Date(t.Year(), t.Month(), t.Day()+1, s.hour, s.minute, 0, 0, t.Location())
+	}
+	return next
+}
+
+/*
+ParseScheduleSpec accepts a schedule specification string and returns the
+corresponding ScheduleSpec. The following forms are supported:
+
+	@every <duration>   run at a fixed interval, e.g. "@every 1h30m"
+	@daily <HH:MM>      run once a day at the given time, e.g. "@daily 07:30"
+
+An error is returned if spec is not in one of these forms, if the duration is
+not positive, or if the time of day is invalid.
+*/
+func ParseScheduleSpec(spec string) (ScheduleSpec, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("invalid schedule spec %q", spec)
+	}
+	switch fields[0] {
+	case "@every":
+		d, err := time.ParseDuration(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule spec %q: %w", spec, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("invalid schedule spec %q: interval must be positive", spec)
+		}
+		return intervalSpec{interval: d}, nil
+	case "@daily":
+		t, err := time.Parse("15:04", fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule spec %q: %w", spec, err)
+		}
+		return dailySpec{hour: t.Hour(), minute: t.Minute()}, nil
+	default:
+		return nil, fmt.Errorf("invalid schedule spec %q", spec)
+	}
+}
+
+// Scheduler runs WriteSummaries periodically. Clock is used to tell the time
+// and to wait for scheduled runs, and any errors returned by WriteSummaries
+// are written to ErrorLog. If Clock is nil, the system clock is used, and if
+// ErrorLog is nil, errors are discarded.
+type Scheduler struct {
+	Clock    Clock
+	ErrorLog io.Writer
+}
+
+// NewScheduler returns a Scheduler that uses the system clock and writes
+// errors to the stderr stream.
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		Clock:    systemClock{},
+		ErrorLog: os.Stderr,
+	}
+}
+
+// Schedule calls WriteSummaries with w and sources at the times given by
+// spec (see ParseScheduleSpec) until ctx is cancelled. Runs never overlap: the
+// next run is only scheduled once the current one has finished, and any
+// scheduled times that passed while it was in progress are skipped. Errors
+// from WriteSummaries are written to the Scheduler's ErrorLog and do not stop
+// the schedule. Schedule returns ctx.Err() once ctx is cancelled, or an error
+// immediately if spec is invalid. Cancelling ctx does not interrupt a call to
+// WriteSummaries that is already in progress; Schedule returns once that call
+// has finished.
+func (s *Scheduler) Schedule(ctx context.Context, spec string, w io.Writer, sources ...Summarizer) error {
+	sched, err := ParseScheduleSpec(spec)
+	if err != nil {
+		return err
+	}
+	clock := s.Clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+	next := sched.Next(clock.Now())
+	for {
+		timer, stop := clock.NewTimer(next.Sub(clock.Now()))
+		select {
+		case <-ctx.Done():
+			stop()
+			return ctx.Err()
+		case <-timer:
+		}
+		err := WriteSummaries(w, sources...)
+		if err != nil && s.ErrorLog != nil {
+			fmt.Fprintln(s.ErrorLog, err)
+		}
+		next = nextAfter(sched, next, clock.Now())
+	}
+}
+
+// nextAfter returns the first time scheduled by sched, counting on from next,
+// that is strictly after now. For interval schedules the missed runs are
+// skipped arithmetically rather than one at a time, so that a short interval
+// doesn't loop once per missed run after a long stall.
+func nextAfter(sched ScheduleSpec, next, now time.Time) time.Time {
+	if next.After(now) {
+		return next
+	}
+	if s, ok := sched.(intervalSpec); ok {
+		missed := now.Sub(next)/s.interval + 1
+		return next.Add(missed * s.interval)
+	}
+	for !next.After(now) {
+		next = sched.Next(next)
+	}
+	return next
+}
+
+// Schedule calls WriteSummaries with w and sources at the times given by
+// spec until ctx is cancelled, using a Scheduler returned by NewScheduler.
+// See Scheduler.Schedule for details.
+func Schedule(ctx context.Context, spec string, w io.Writer, sources ...Summarizer) error {
+	return NewScheduler().Schedule(ctx, spec, w, sources...)
+}
@@ -0,0 +1,376 @@
+package morningpost_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/morningpost"
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeClock is a morningpost.Clock whose time only moves when Advance is
+// called. Every call to NewTimer is announced on the waiting channel so tests
+// can tell when the scheduler is idle and waiting for its next run.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []fakeTimer
+	waiting chan struct{}
+}
+
+type fakeTimer struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, waiting: make(chan struct{}, 100)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	c.mu.Lock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+	} else {
+		c.timers = append(c.timers, fakeTimer{deadline: c.now.Add(d), ch: ch})
+	}
+	c.mu.Unlock()
+	c.waiting <- struct{}{}
+	stop := func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i, t := range c.timers {
+			if t.ch == ch {
+				c.timers = append(c.timers[:i], c.timers[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+	return ch, stop
+}
+
+// PendingTimers returns the number of timers that have neither fired nor
+// been stopped.
+func (c *fakeClock) PendingTimers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.ch <- c.now
+	}
+	c.timers = pending
+}
+
+// countingSummarizer records how many times, and how many times at once, its
+// Summary method is called. If release is non-nil, each call blocks until
+// release is closed. If clock is non-nil, the time of each call is recorded in
+// times, and the first call advances clock by stall to simulate a slow run.
+type countingSummarizer struct {
+	mu        sync.Mutex
+	runs      int
+	active    int
+	maxActive int
+	times     []time.Time
+	clock     *fakeClock
+	stall     time.Duration
+	started   chan struct{}
+	release   chan struct{}
+}
+
+func newCountingSummarizer() *countingSummarizer {
+	return &countingSummarizer{started: make(chan struct{}, 100)}
+}
+
+func (c *countingSummarizer) Runs() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.runs
+}
+
+func (c *countingSummarizer) Summary() (string, error) {
+	c.mu.Lock()
+	c.runs++
+	c.active++
+	if c.active > c.maxActive {
+		c.maxActive = c.active
+	}
+	if c.clock != nil {
+		c.times = append(c.times, c.clock.Now())
+		c.clock.Advance(c.stall)
+		c.stall = 0
+	}
+	c.mu.Unlock()
+	c.started <- struct{}{}
+	if c.release != nil {
+		<-c.release
+	}
+	c.mu.Lock()
+	c.active--
+	c.mu.Unlock()
+	return "news", nil
+}
+
+func TestSchedulerSchedule_RunsOncePerIntervalUntilContextIsCancelled(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock(time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC))
+	s := &morningpost.Scheduler{Clock: clock, ErrorLog: new(bytes.Buffer)}
+	sum := newCountingSummarizer()
+	output := new(bytes.Buffer)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Schedule(ctx, "@every 1m", output, sum)
+	}()
+	for i := 0; i < 5; i++ {
+		<-clock.waiting
+		clock.Advance(time.Minute)
+		<-sum.started
+	}
+	<-clock.waiting
+	cancel()
+	err := <-done
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want error %v, got %v", context.Canceled, err)
+	}
+	want := strings.Repeat("news\n", 5)
+	got := output.String()
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestSchedulerSchedule_RunsOncePerDayAtTheGivenTime(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock(time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC))
+	s := &morningpost.Scheduler{Clock: clock, ErrorLog: new(bytes.Buffer)}
+	sum := newCountingSummarizer()
+	sum.clock = clock
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Schedule(ctx, "@daily 07:30", new(bytes.Buffer), sum)
+	}()
+	<-clock.waiting
+	clock.Advance(time.Hour)
+	if n := sum.Runs(); n != 0 {
+		t.Fatalf("want no runs before 07:30, got %d", n)
+	}
+	clock.Advance(30 * time.Minute)
+	<-sum.started
+	for i := 0; i < 2; i++ {
+		<-clock.waiting
+		clock.Advance(12 * time.Hour)
+		clock.Advance(12 * time.Hour)
+		<-sum.started
+	}
+	<-clock.waiting
+	cancel()
+	err := <-done
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want error %v, got %v", context.Canceled, err)
+	}
+	want := []time.Time{
+		time.Date(2026, 10, 16, 7, 30, 0, 0, time.UTC),
+		time.Date(2026, 10, 17, 7, 30, 0, 0, time.UTC),
+		time.Date(2026, 10, 18, 7, 30, 0, 0, time.UTC),
+	}
+	if !cmp.Equal(want, sum.times) {
+		t.Error(cmp.Diff(want, sum.times))
+	}
+}
+
+func TestSchedulerSchedule_SkipsMissedIntervalsAfterASlowRun(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	s := &morningpost.Scheduler{Clock: clock, ErrorLog: new(bytes.Buffer)}
+	sum := newCountingSummarizer()
+	sum.clock = clock
+	sum.stall = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Schedule(ctx, "@every 1ms", new(bytes.Buffer), sum)
+	}()
+	<-clock.waiting
+	clock.Advance(time.Millisecond)
+	<-sum.started
+	<-clock.waiting
+	clock.Advance(time.Millisecond)
+	<-sum.started
+	<-clock.waiting
+	cancel()
+	<-done
+	want := []time.Time{
+		start.Add(time.Millisecond),
+		start.Add(time.Hour + 2*time.Millisecond),
+	}
+	if !cmp.Equal(want, sum.times) {
+		t.Error(cmp.Diff(want, sum.times))
+	}
+}
+
+func TestSchedulerSchedule_UsesSystemClockGivenZeroValueScheduler(t *testing.T) {
+	t.Parallel()
+	s := &morningpost.Scheduler{}
+	sum := newCountingSummarizer()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Schedule(ctx, "@every 1ms", new(bytes.Buffer), sum)
+	}()
+	<-sum.started
+	cancel()
+	for {
+		select {
+		case <-sum.started:
+		case err := <-done:
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("want error %v, got %v", context.Canceled, err)
+			}
+			return
+		}
+	}
+}
+
+func TestSchedulerSchedule_StopsPendingTimerWhenContextIsCancelled(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock(time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC))
+	s := &morningpost.Scheduler{Clock: clock, ErrorLog: new(bytes.Buffer)}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Schedule(ctx, "@daily 07:30", new(bytes.Buffer), newCountingSummarizer())
+	}()
+	<-clock.waiting
+	cancel()
+	<-done
+	if n := clock.PendingTimers(); n != 0 {
+		t.Errorf("want no pending timers after cancellation, got %d", n)
+	}
+}
+
+func TestSchedulerSchedule_SkipsScheduledRunsWhileARunIsInProgress(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock(time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC))
+	s := &morningpost.Scheduler{Clock: clock, ErrorLog: new(bytes.Buffer)}
+	sum := newCountingSummarizer()
+	sum.release = make(chan struct{})
+	output := new(bytes.Buffer)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Schedule(ctx, "@every 1m", output, sum)
+	}()
+	<-clock.waiting
+	clock.Advance(time.Minute)
+	<-sum.started
+	for i := 0; i < 3; i++ {
+		clock.Advance(time.Minute)
+	}
+	close(sum.release)
+	<-clock.waiting
+	clock.Advance(time.Minute)
+	<-sum.started
+	<-clock.waiting
+	cancel()
+	err := <-done
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want error %v, got %v", context.Canceled, err)
+	}
+	if sum.runs != 2 {
+		t.Errorf("want 2 runs, got %d", sum.runs)
+	}
+	if sum.maxActive != 1 {
+		t.Errorf("want at most 1 run at a time, got %d", sum.maxActive)
+	}
+}
+
+func TestSchedulerSchedule_WritesSummaryErrorsToErrorLog(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock(time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC))
+	errorLog := new(bytes.Buffer)
+	s := &morningpost.Scheduler{Clock: clock, ErrorLog: errorLog}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Schedule(ctx, "@every 1m", new(bytes.Buffer), &mockSummarizer{err: errors.New("oh no!")})
+	}()
+	<-clock.waiting
+	clock.Advance(time.Minute)
+	<-clock.waiting
+	cancel()
+	<-done
+	want := "oh no!\n"
+	got := errorLog.String()
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestSchedulerSchedule_ReturnsErrorGivenInvalidSpec(t *testing.T) {
+	t.Parallel()
+	s := &morningpost.Scheduler{Clock: newFakeClock(time.Now()), ErrorLog: new(bytes.Buffer)}
+	err := s.Schedule(context.Background(), "bogus", new(bytes.Buffer))
+	if err == nil {
+		t.Fatal("want error for invalid spec, got nil")
+	}
+}
+
+func TestParseScheduleSpec_ReturnsExpectedNextTimes(t *testing.T) {
+	t.Parallel()
+	from := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	tcs := []struct {
+		spec string
+		want time.Time
+	}{
+		{spec: "@every 90m", want: time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)},
+		{spec: "@daily 07:30", want: time.Date(2026, 10, 17, 7, 30, 0, 0, time.UTC)},
+		{spec: "@daily 08:00", want: time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)},
+		{spec: "@daily 21:15", want: time.Date(2026, 10, 16, 21, 15, 0, 0, time.UTC)},
+	}
+	for _, tc := range tcs {
+		spec, err := morningpost.ParseScheduleSpec(tc.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := spec.Next(from)
+		if !tc.want.Equal(got) {
+			t.Errorf("%s: want next time %v, got %v", tc.spec, tc.want, got)
+		}
+	}
+}
+
+func TestParseScheduleSpec_ReturnsErrorGivenInvalidSpec(t *testing.T) {
+	t.Parallel()
+	for _, spec := range []string{"", "@every", "@every -1m", "@every soon", "@daily 25:00", "@hourly 1"} {
+		_, err := morningpost.ParseScheduleSpec(spec)
+		if err == nil {
+			t.Errorf("want error parsing invalid spec %q, got nil", spec)
+		}
+	}
+}